@@ -0,0 +1,251 @@
+package hanu
+
+import (
+	"encoding/json"
+	"errors"
+)
+
+// errNoTriggerID is returned by Conversation.OpenModal when the
+// conversation didn't originate from an interaction carrying a
+// trigger_id (e.g. a plain text command)
+var errNoTriggerID = errors.New("hanu: conversation has no trigger_id to open a modal with")
+
+// errModalsUnsupported is returned by Bot.openModal when the Bot's
+// Transport doesn't implement ModalOpener at all
+var errModalsUnsupported = errors.New("hanu: transport does not support opening modals")
+
+// Block is a single Block Kit layout block. The concrete types below
+// satisfy it; a Transport that doesn't understand Block Kit is free to
+// ignore a Message's Blocks and fall back to its plain text. Each type's
+// MarshalJSON fills in its own Type field from blockType, so callers get
+// typed values and never have to set Type by hand.
+type Block interface {
+	blockType() string
+}
+
+// TextObject is Slack's composition object for block text
+type TextObject struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+// SelectOption is one choice in a static select Element
+type SelectOption struct {
+	Text  TextObject `json:"text"`
+	Value string     `json:"value"`
+}
+
+// Element is a Block Kit interactive element: a button, or a
+// static/user/channel select menu, depending on Type.
+type Element struct {
+	Type     string         `json:"type"`
+	ActionID string         `json:"action_id,omitempty"`
+	Text     *TextObject    `json:"text,omitempty"`
+	Value    string         `json:"value,omitempty"`
+	Options  []SelectOption `json:"options,omitempty"`
+}
+
+// SectionBlock is a `section` layout block, typically text plus an
+// optional accessory element (button, select, ...)
+type SectionBlock struct {
+	Type      string      `json:"type"`
+	Text      *TextObject `json:"text,omitempty"`
+	Accessory *Element    `json:"accessory,omitempty"`
+}
+
+func (SectionBlock) blockType() string { return "section" }
+
+// MarshalJSON fills in Type from blockType before encoding
+func (s SectionBlock) MarshalJSON() ([]byte, error) {
+	type alias SectionBlock
+	s.Type = s.blockType()
+	return json.Marshal(alias(s))
+}
+
+// ActionsBlock holds up to five interactive Elements rendered side by side
+type ActionsBlock struct {
+	Type     string    `json:"type"`
+	Elements []Element `json:"elements"`
+}
+
+func (ActionsBlock) blockType() string { return "actions" }
+
+// MarshalJSON fills in Type from blockType before encoding
+func (a ActionsBlock) MarshalJSON() ([]byte, error) {
+	type alias ActionsBlock
+	a.Type = a.blockType()
+	return json.Marshal(alias(a))
+}
+
+// InputBlock collects a single value inside a modal View
+type InputBlock struct {
+	Type    string     `json:"type"`
+	Label   TextObject `json:"label"`
+	Element Element    `json:"element"`
+}
+
+func (InputBlock) blockType() string { return "input" }
+
+// MarshalJSON fills in Type from blockType before encoding
+func (i InputBlock) MarshalJSON() ([]byte, error) {
+	type alias InputBlock
+	i.Type = i.blockType()
+	return json.Marshal(alias(i))
+}
+
+// DividerBlock renders a horizontal rule between blocks
+type DividerBlock struct {
+	Type string `json:"type"`
+}
+
+func (DividerBlock) blockType() string { return "divider" }
+
+// MarshalJSON fills in Type from blockType before encoding
+func (d DividerBlock) MarshalJSON() ([]byte, error) {
+	type alias DividerBlock
+	d.Type = d.blockType()
+	return json.Marshal(alias(d))
+}
+
+// ImageBlock renders a standalone image
+type ImageBlock struct {
+	Type     string `json:"type"`
+	ImageURL string `json:"image_url"`
+	AltText  string `json:"alt_text"`
+}
+
+func (ImageBlock) blockType() string { return "image" }
+
+// MarshalJSON fills in Type from blockType before encoding
+func (i ImageBlock) MarshalJSON() ([]byte, error) {
+	type alias ImageBlock
+	i.Type = i.blockType()
+	return json.Marshal(alias(i))
+}
+
+// View is a Block Kit modal, opened via `views.open`
+type View struct {
+	Type       string      `json:"type"`
+	CallbackID string      `json:"callback_id"`
+	Title      TextObject  `json:"title"`
+	Blocks     []Block     `json:"blocks"`
+	Submit     *TextObject `json:"submit,omitempty"`
+	Close      *TextObject `json:"close,omitempty"`
+}
+
+// ActionContext carries the details of a `block_actions` interaction:
+// which element fired, who triggered it, and enough context to reply or
+// open a follow-up modal.
+type ActionContext struct {
+	ActionID  string
+	TriggerID string
+	User      string
+	Value     string
+
+	reply Sender
+	modal modalSender
+}
+
+// Reply sends text back to the user who triggered the action
+func (a ActionContext) Reply(text string) error {
+	msg := Message{Type: "message"}
+	msg.SetUser(a.User)
+	msg.SetText(text)
+	return a.reply(msg)
+}
+
+// OpenModal opens view as a follow-up to the action, using its TriggerID
+func (a ActionContext) OpenModal(view View) error {
+	return a.modal(a.TriggerID, view)
+}
+
+// NewActionContext builds an ActionContext for a Transport to hand to a
+// registered OnBlockAction handler
+func NewActionContext(actionID, triggerID, user, value string, reply Sender, openModal func(triggerID string, view View) error) ActionContext {
+	return ActionContext{
+		ActionID:  actionID,
+		TriggerID: triggerID,
+		User:      user,
+		Value:     value,
+		reply:     reply,
+		modal:     openModal,
+	}
+}
+
+// ViewSubmissionContext carries a submitted modal's state
+type ViewSubmissionContext struct {
+	CallbackID string
+	User       string
+	Values     map[string]string
+}
+
+// modalSender opens a Block Kit modal for a given trigger_id
+type modalSender func(triggerID string, view View) error
+
+// ModalOpener is implemented by a Transport that can open Slack Block
+// Kit modals via `views.open`
+type ModalOpener interface {
+	OpenModal(triggerID string, view View) error
+}
+
+// BlockActionRegistrar is implemented by a Transport that can dispatch
+// Block Kit `block_actions` interactions
+type BlockActionRegistrar interface {
+	OnBlockAction(actionID string, handler func(ActionContext))
+}
+
+// ViewSubmissionRegistrar is implemented by a Transport that can dispatch
+// Block Kit `view_submission` interactions
+type ViewSubmissionRegistrar interface {
+	OnViewSubmission(callbackID string, handler func(ViewSubmissionContext))
+}
+
+// OnBlockAction registers handler for a Block Kit button or select menu
+// interaction, if the Bot's Transport supports Block Kit interactivity
+func (b *Bot) OnBlockAction(actionID string, handler func(ActionContext)) {
+	if r, ok := b.Transport.(BlockActionRegistrar); ok {
+		r.OnBlockAction(actionID, handler)
+	}
+}
+
+// OnViewSubmission registers handler for a modal's `view_submission`, if
+// the Bot's Transport supports Block Kit interactivity
+func (b *Bot) OnViewSubmission(callbackID string, handler func(ViewSubmissionContext)) {
+	if r, ok := b.Transport.(ViewSubmissionRegistrar); ok {
+		r.OnViewSubmission(callbackID, handler)
+	}
+}
+
+// openModal opens view via the Transport, if it supports Block Kit modals
+func (b *Bot) openModal(triggerID string, view View) error {
+	mo, ok := b.Transport.(ModalOpener)
+	if !ok {
+		return errModalsUnsupported
+	}
+
+	return mo.OpenModal(triggerID, view)
+}
+
+// ReplyWithBlocks sends a Block Kit message back into the conversation
+// instead of plain text, for buttons, selects and richer layouts.
+// Transports that don't understand Block Kit ignore the Blocks field.
+func (c Conversation) ReplyWithBlocks(blocks []Block) error {
+	msg := c.Message
+	msg.SetText("")
+	msg.SetBlocks(blocks)
+	return c.reply(msg)
+}
+
+// OpenModal opens view via the conversation's Transport, using the
+// trigger_id of the Message the conversation was built from. Bot builds
+// every Conversation from a received text message, and nothing in hanu
+// sets a Message's trigger_id, so this returns errNoTriggerID for every
+// Conversation that Bot hands to a command Handler. Block actions open
+// modals through ActionContext.OpenModal instead.
+func (c Conversation) OpenModal(view View) error {
+	if c.TriggerID() == "" || c.modal == nil {
+		return errNoTriggerID
+	}
+
+	return c.modal(c.TriggerID(), view)
+}
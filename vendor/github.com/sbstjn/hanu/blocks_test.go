@@ -0,0 +1,78 @@
+package hanu
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+// TestBlockMarshalSetsType checks each Block's MarshalJSON fills in its
+// Type from blockType, so callers never have to set it by hand.
+func TestBlockMarshalSetsType(t *testing.T) {
+	cases := []struct {
+		name  string
+		block Block
+		want  string
+	}{
+		{"section", SectionBlock{}, "section"},
+		{"actions", ActionsBlock{}, "actions"},
+		{"input", InputBlock{}, "input"},
+		{"divider", DividerBlock{}, "divider"},
+		{"image", ImageBlock{}, "image"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			raw, err := json.Marshal(c.block)
+			if err != nil {
+				t.Fatalf("Marshal: %v", err)
+			}
+
+			var decoded struct {
+				Type string `json:"type"`
+			}
+			if err := json.Unmarshal(raw, &decoded); err != nil {
+				t.Fatalf("Unmarshal: %v", err)
+			}
+			if decoded.Type != c.want {
+				t.Fatalf("type = %q, want %q", decoded.Type, c.want)
+			}
+		})
+	}
+}
+
+// TestViewMarshalSetsBlockTypes checks Type is filled in for every Block
+// even when nested inside a View's Blocks slice.
+func TestViewMarshalSetsBlockTypes(t *testing.T) {
+	view := View{
+		Type:  "modal",
+		Title: TextObject{Type: "plain_text", Text: "Title"},
+		Blocks: []Block{
+			SectionBlock{Text: &TextObject{Type: "mrkdwn", Text: "hi"}},
+			DividerBlock{},
+		},
+	}
+
+	raw, err := json.Marshal(view)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+
+	var decoded struct {
+		Blocks []struct {
+			Type string `json:"type"`
+		} `json:"blocks"`
+	}
+	if err := json.Unmarshal(raw, &decoded); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+
+	want := []string{"section", "divider"}
+	if len(decoded.Blocks) != len(want) {
+		t.Fatalf("got %d blocks, want %d", len(decoded.Blocks), len(want))
+	}
+	for i, w := range want {
+		if decoded.Blocks[i].Type != w {
+			t.Fatalf("block %d type = %q, want %q", i, decoded.Blocks[i].Type, w)
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package hanu
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Handler processes a matched command's Conversation
+type Handler func(Conversation)
+
+// CommandInterface is satisfied by anything Bot.Register can dispatch: a
+// pattern to match incoming text against (Get), a Handler to run once it
+// does (Handle), and a description for Bot.sendHelp.
+type CommandInterface interface {
+	Get() *Command
+	Handle(Conversation)
+	Description() string
+}
+
+// Command is a text pattern registered with Bot.Command or Bot.Register.
+// Patterns may contain "{name}" placeholders, captured into the
+// Conversation's Match for the Handler to read back.
+type Command struct {
+	command     string
+	description string
+	handler     Handler
+}
+
+// NewCommand builds a Command from a pattern, a help description and the
+// Handler to run when the pattern matches
+func NewCommand(command, description string, handler Handler) *Command {
+	return &Command{
+		command:     command,
+		description: description,
+		handler:     handler,
+	}
+}
+
+// Get returns the Command itself, satisfying CommandInterface
+func (c *Command) Get() *Command { return c }
+
+// Description returns the Command's help text
+func (c *Command) Description() string { return c.description }
+
+// Handle runs the Command's Handler
+func (c *Command) Handle(conv Conversation) { c.handler(conv) }
+
+// Text returns the Command's raw pattern, used for the auto-generated
+// help command list
+func (c *Command) Text() string { return c.command }
+
+// placeholder matches a "{name}" token in a Command pattern
+var placeholder = regexp.MustCompile(`\{(\w+)\}`)
+
+// regexp compiles the Command's pattern into a regular expression, with
+// each "{name}" placeholder turned into a named capture group
+func (c *Command) regexp() *regexp.Regexp {
+	var pattern strings.Builder
+	pattern.WriteString("^")
+
+	last := 0
+	for _, loc := range placeholder.FindAllStringSubmatchIndex(c.command, -1) {
+		pattern.WriteString(regexp.QuoteMeta(c.command[last:loc[0]]))
+		name := c.command[loc[2]:loc[3]]
+		pattern.WriteString(fmt.Sprintf("(?P<%s>.+)", name))
+		last = loc[1]
+	}
+	pattern.WriteString(regexp.QuoteMeta(c.command[last:]))
+	pattern.WriteString("$")
+
+	return regexp.MustCompile(pattern.String())
+}
+
+// Match checks text against the Command's pattern, returning the values
+// captured by any "{name}" placeholders
+func (c *Command) Match(text string) (Match, error) {
+	re := c.regexp()
+	groups := re.FindStringSubmatch(text)
+	if groups == nil {
+		return Match{}, fmt.Errorf("hanu: %q does not match command %q", text, c.command)
+	}
+
+	values := make(map[string]string, len(groups))
+	for i, name := range re.SubexpNames() {
+		if i == 0 || name == "" {
+			continue
+		}
+		values[name] = groups[i]
+	}
+
+	return Match{command: c.command, values: values}, nil
+}
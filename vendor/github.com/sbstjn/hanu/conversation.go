@@ -0,0 +1,32 @@
+package hanu
+
+import "fmt"
+
+// Conversation is handed to a matched CommandInterface's Handler: the
+// Match captured out of the command pattern, the Message that triggered
+// it, and enough to reply or open a follow-up modal.
+type Conversation struct {
+	Match
+	Message
+
+	reply Sender
+	modal modalSender
+}
+
+// NewConversation builds a Conversation for Bot.searchCommand to hand to
+// a matched CommandInterface's Handler
+func NewConversation(match Match, msg Message, reply Sender, modal modalSender) Conversation {
+	return Conversation{
+		Match:   match,
+		Message: msg,
+		reply:   reply,
+		modal:   modal,
+	}
+}
+
+// Reply sends text back into the conversation
+func (c Conversation) Reply(text string, args ...interface{}) error {
+	msg := c.Message
+	msg.SetText(fmt.Sprintf(text, args...))
+	return c.reply(msg)
+}
@@ -0,0 +1,62 @@
+package hanu
+
+import (
+	"fmt"
+	"log"
+)
+
+// Logger receives structured lifecycle events from a Bot (and, if its
+// Transport implements LoggerSetter, from the Transport too): handshake
+// success, reconnect attempts, command dispatch, and similar. Each kv pair
+// is a key followed by its value, e.g. Info("command dispatched", "command",
+// cmd, "latency_ms", elapsed). Install a custom Logger with Bot.SetLogger;
+// a stdlib-backed implementation is used until then.
+type Logger interface {
+	Debug(msg string, kv ...any)
+	Info(msg string, kv ...any)
+	Warn(msg string, kv ...any)
+	Error(msg string, kv ...any)
+}
+
+// LoggerSetter is implemented by a Transport that wants the Bot's
+// configured Logger forwarded to it, so it can emit structured events for
+// things only it sees (handshake, ping/pong, ...). Bot.SetLogger forwards
+// automatically when the Transport supports it.
+type LoggerSetter interface {
+	SetLogger(Logger)
+}
+
+// stdLogger is the default Logger, backed by the standard library's log package
+type stdLogger struct{}
+
+func (stdLogger) Debug(msg string, kv ...any) { stdLogger{}.print("DEBUG", msg, kv) }
+func (stdLogger) Info(msg string, kv ...any)  { stdLogger{}.print("INFO", msg, kv) }
+func (stdLogger) Warn(msg string, kv ...any)  { stdLogger{}.print("WARN", msg, kv) }
+func (stdLogger) Error(msg string, kv ...any) { stdLogger{}.print("ERROR", msg, kv) }
+
+func (stdLogger) print(level, msg string, kv []any) {
+	log.Printf("[%s] %s%s", level, msg, formatFields(kv))
+}
+
+// formatFields renders kv pairs as ` key=value key=value ...`. A trailing
+// unpaired key is rendered with a `<missing>` value rather than dropped.
+func formatFields(kv []any) string {
+	out := ""
+	for i := 0; i < len(kv); i += 2 {
+		if i+1 < len(kv) {
+			out += fmt.Sprintf(" %v=%v", kv[i], kv[i+1])
+		} else {
+			out += fmt.Sprintf(" %v=<missing>", kv[i])
+		}
+	}
+	return out
+}
+
+// SetLogger installs logger for the Bot's own lifecycle events, and
+// forwards it to the Transport if the Transport implements LoggerSetter
+func (b *Bot) SetLogger(logger Logger) {
+	b.logger = logger
+	if ls, ok := b.Transport.(LoggerSetter); ok {
+		ls.SetLogger(logger)
+	}
+}
@@ -0,0 +1,39 @@
+// Package zerolog adapts github.com/rs/zerolog to hanu.Logger, for bots
+// that want hanu's lifecycle events folded into a structured log pipeline.
+package zerolog
+
+import (
+	"github.com/rs/zerolog"
+)
+
+// Logger implements hanu.Logger on top of a zerolog.Logger
+type Logger struct {
+	log zerolog.Logger
+}
+
+// New wraps an existing zerolog.Logger as a hanu.Logger
+func New(log zerolog.Logger) Logger {
+	return Logger{log: log}
+}
+
+func (l Logger) Debug(msg string, kv ...any) { l.event(l.log.Debug(), msg, kv) }
+func (l Logger) Info(msg string, kv ...any)  { l.event(l.log.Info(), msg, kv) }
+func (l Logger) Warn(msg string, kv ...any)  { l.event(l.log.Warn(), msg, kv) }
+func (l Logger) Error(msg string, kv ...any) { l.event(l.log.Error(), msg, kv) }
+
+// event attaches kv pairs to a zerolog.Event as fields and sends it. A
+// trailing unpaired key is logged under "extra" rather than dropped.
+func (l Logger) event(e *zerolog.Event, msg string, kv []any) {
+	for i := 0; i+1 < len(kv); i += 2 {
+		key, ok := kv[i].(string)
+		if !ok {
+			continue
+		}
+		e = e.Interface(key, kv[i+1])
+	}
+	if len(kv)%2 == 1 {
+		e = e.Interface("extra", kv[len(kv)-1])
+	}
+
+	e.Msg(msg)
+}
@@ -1,106 +1,53 @@
 package hanu
 
 import (
-	"encoding/json"
-	"errors"
 	"fmt"
-	"io/ioutil"
-	"log"
-	"net"
-	"net/http"
 	"time"
-
-	"golang.org/x/net/websocket"
 )
 
-type handshakeResponseSelf struct {
-	ID string `json:"id"`
-}
-
-type handshakeResponse struct {
-	Ok    bool                  `json:"ok"`
-	Error string                `json:"error"`
-	URL   string                `json:"url"`
-	Self  handshakeResponseSelf `json:"self"`
-}
-
-// Bot is the main object
+// Bot is the main object. It drives command dispatch and help generation
+// against whatever Transport it is given, so the same Bot works unchanged
+// against Slack, Mattermost, Rocket.Chat, or any other backend that
+// implements Transport.
 type Bot struct {
-	Socket   *websocket.Conn
-	Token    string
-	ID       string
-	Commands []CommandInterface
-}
-
-// New creates a new bot
-func New(token string) (*Bot, error) {
-	bot := Bot{
-		Token: token,
-	}
-
-	return bot.Handshake()
+	Transport Transport
+	ID        string
+	Commands  []CommandInterface
+
+	// MaxReconnectAttempts bounds how many times Listen will try to
+	// reconnect after losing the Transport connection. 0 means retry
+	// forever.
+	MaxReconnectAttempts int
+
+	reconnectState *reconnectState
+	logger         Logger
+	middleware     []Middleware
 }
 
-// Handshake connects to the Slack API to get a socket connection
-func (b *Bot) Handshake() (*Bot, error) {
-	dialer := &net.Dialer{
-		Timeout:   30 * time.Second,
-		KeepAlive: 30 * time.Second,
-	}
-	transport := &http.Transport{
-		Dial:                dialer.Dial,
-		TLSHandshakeTimeout: 15 * time.Second,
-	}
-	client := &http.Client{
-		Transport: transport,
-		Timeout:   30 * time.Second,
+// New creates a new bot bound to transport and connects it
+func New(transport Transport) (*Bot, error) {
+	bot := &Bot{
+		Transport:      transport,
+		reconnectState: newReconnectState(),
+		logger:         stdLogger{},
 	}
 
-	// Check for HTTP error on connection
-	res, err := client.Get(fmt.Sprintf("https://slack.com/api/rtm.start?token=%s", b.Token))
-	if err != nil {
-		return nil, errors.New("Failed to connect to Slack RTM API")
+	if err := transport.Connect(); err != nil {
+		return nil, err
 	}
-	defer res.Body.Close()
+	bot.logger.Info("handshake succeeded")
 
-	// Check for HTTP status code
-	if res.StatusCode != 200 {
-		return nil, fmt.Errorf("Failed with HTTP Code: %d", res.StatusCode)
-	}
-
-	// Read response body
-	body, err := ioutil.ReadAll(res.Body)
-	if err != nil {
-		return nil, fmt.Errorf("Failed to read body from response")
-	}
-
-	// Parse response
-	var response handshakeResponse
-	err = json.Unmarshal(body, &response)
-	if err != nil {
-		return nil, fmt.Errorf("Failed to unmarshal JSON: %s", body)
-	}
-
-	// Check for Slack error
-	if !response.Ok {
-		return nil, errors.New(response.Error)
+	if idr, ok := transport.(Identifier); ok {
+		id, err := idr.BotUserID()
+		if err != nil {
+			return nil, fmt.Errorf("hanu: failed to resolve bot's own user ID: %v", err)
+		}
+		bot.ID = id
 	}
 
-	// Assign Slack user ID
-	b.ID = response.Self.ID
-
-	// Connect to websocket
-	config, err := websocket.NewConfig(response.URL, "https://api.slack.com/")
-	if err != nil {
-		return nil, fmt.Errorf("Failed to create config for Websocket: %v", err)
-	}
-	config.Dialer = dialer
-	b.Socket, err = websocket.DialConfig(config)
-	if err != nil {
-		return nil, fmt.Errorf("Failed to connect to Websocket: %v", err)
-	}
+	go bot.sendLoop()
 
-	return b, nil
+	return bot, nil
 }
 
 // Process incoming message
@@ -111,7 +58,7 @@ func (b *Bot) process(message Message) {
 
 	// Strip @BotName from public message
 	message.StripMention(b.ID)
-	// Strip Slack's link markup
+	// Strip Slack-style <url|label> link markup
 	message.StripLinkMarkup()
 
 	// Check if the message requests the auto-generated help command list
@@ -132,7 +79,15 @@ func (b *Bot) searchCommand(msg Message) {
 
 		match, err := cmd.Get().Match(msg.Text())
 		if err == nil {
-			cmd.Handle(NewConversation(match, msg, b.Socket))
+			conv := NewConversation(match, msg, Sender(b.enqueue), modalSender(b.openModal))
+			start := time.Now()
+			b.wrap(cmd.Handle)(conv)
+			b.logger.Info("command dispatched",
+				"bot_id", b.ID,
+				"command", cmd.Get().Text(),
+				"message_type", msg.Type,
+				"latency_ms", time.Since(start).Milliseconds(),
+			)
 		}
 	}
 }
@@ -158,55 +113,28 @@ func (b *Bot) sendHelp(msg Message) {
 	}
 
 	msg.SetText(help)
-	websocket.JSON.Send(b.Socket, msg)
+	if err := b.enqueue(msg); err != nil {
+		b.logger.Error("unable to send help message", "error", err)
+	}
 }
 
-// Listen for message on socket
+// Listen for messages coming in from the Transport. A lost connection is
+// not fatal: Listen transparently reconnects with backoff and keeps
+// serving, only returning once MaxReconnectAttempts is exceeded.
 func (b *Bot) Listen() error {
-	var msg Message
-	pong := make(chan struct{})
-
-	go func() {
-		time.Sleep(5 * time.Second)
-		count := uint64(100)
-		for {
-			count++
-			if err := websocket.JSON.Send(b.Socket, &Message{ID: count, Type: "ping"}); err != nil {
-				log.Printf("Unable to send a ping to the Slack API: %v")
-				if err := b.Socket.Close(); err != nil {
-					log.Printf("Unable to close websocket: %v")
-				}
-			}
-			select {
-			case <-pong:
-				time.Sleep(time.Minute)
-			case <-time.After(time.Minute):
-				log.Printf("Waited more than a minute for a pong, exiting")
-				if err := b.Socket.Close(); err != nil {
-					log.Printf("Unable to close websocket: %v")
-				}
-				return
-			}
-		}
-	}()
-
 	for {
-		if err := websocket.JSON.Receive(b.Socket, &msg); err != nil {
-			return err
-		}
+		b.reconnectState.transportMu.RLock()
+		msg, err := b.Transport.Receive()
+		b.reconnectState.transportMu.RUnlock()
 
-		switch msg.Type {
-		case "pong":
-			select {
-			case pong <- struct{}{}:
-			default:
+		if err != nil {
+			if rerr := b.reconnect(err); rerr != nil {
+				return rerr
 			}
-		default:
-			go b.process(msg)
+			continue
 		}
 
-		// Clean up message after processign it
-		msg = Message{}
+		go b.process(msg)
 	}
 }
 
@@ -0,0 +1,17 @@
+package hanu
+
+// Match holds the named values a Command's pattern captured out of the
+// text that triggered it. A pattern like "deploy {env}" matching
+// "deploy staging" captures env="staging", retrievable from a Handler
+// via Conversation.String.
+type Match struct {
+	command string
+	values  map[string]string
+}
+
+// String returns the named placeholder's captured value, and whether it
+// was present in the pattern at all.
+func (m Match) String(name string) (string, bool) {
+	v, ok := m.values[name]
+	return v, ok
+}
@@ -0,0 +1,83 @@
+package hanu
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// linkMarkup matches Slack's link markup, e.g. "<http://example.com|text>"
+var linkMarkup = regexp.MustCompile(`<[^|>]+\|([^>]+)>`)
+
+// Message is a single chat message. A Transport either decodes one
+// straight off the wire when its payload is JSON compatible (Slack's
+// Events API `event` object, for instance) or builds one up via the
+// Set* methods when it isn't (Mattermost, Rocket.Chat).
+type Message struct {
+	ID        uint64  `json:"id,omitempty"`
+	Type      string  `json:"type,omitempty"`
+	ChannelID string  `json:"channel,omitempty"`
+	UserID    string  `json:"user,omitempty"`
+	RawText   string  `json:"text,omitempty"`
+	Ts        string  `json:"ts,omitempty"`
+	Blocks    []Block `json:"blocks,omitempty"`
+
+	triggerID string
+}
+
+// Text returns the message's body
+func (m Message) Text() string { return m.RawText }
+
+// SetText replaces the message's body
+func (m *Message) SetText(text string) { m.RawText = text }
+
+// User returns the ID of the user the message is from
+func (m Message) User() string { return m.UserID }
+
+// SetUser sets the ID of the user the message is to or from
+func (m *Message) SetUser(user string) { m.UserID = user }
+
+// Channel returns the ID of the channel the message belongs to
+func (m Message) Channel() string { return m.ChannelID }
+
+// SetChannel sets the ID of the channel the message belongs to
+func (m *Message) SetChannel(channel string) { m.ChannelID = channel }
+
+// SetBlocks attaches Block Kit blocks to the message. Transports that
+// don't understand Block Kit are free to ignore this and fall back to
+// the plain Text.
+func (m *Message) SetBlocks(blocks []Block) { m.Blocks = blocks }
+
+// TriggerID returns the trigger_id of the interaction the message
+// originated from, if any; empty for a plain text message.
+func (m Message) TriggerID() string { return m.triggerID }
+
+// IsDirectMessage returns true if the message was sent in a direct
+// message channel. Slack, Mattermost and Rocket.Chat all prefix DM
+// channel IDs with "D".
+func (m Message) IsDirectMessage() bool {
+	return strings.HasPrefix(m.ChannelID, "D")
+}
+
+// IsRelevantFor returns true if id should respond to the message: it's
+// either a direct message, or a public message mentioning id
+func (m Message) IsRelevantFor(id string) bool {
+	return m.IsDirectMessage() || strings.Contains(m.RawText, fmt.Sprintf("<@%s>", id))
+}
+
+// StripMention removes an "<@id>" mention of id from the message text
+func (m *Message) StripMention(id string) {
+	m.RawText = strings.TrimSpace(strings.ReplaceAll(m.RawText, fmt.Sprintf("<@%s>", id), ""))
+}
+
+// StripLinkMarkup removes Slack's link markup, turning
+// "<http://example.com|example.com>" into "example.com"
+func (m *Message) StripLinkMarkup() {
+	m.RawText = linkMarkup.ReplaceAllString(m.RawText, "$1")
+}
+
+// IsHelpRequest returns true if the message is asking for the
+// auto-generated help command list
+func (m Message) IsHelpRequest() bool {
+	return strings.TrimSpace(m.RawText) == "help"
+}
@@ -0,0 +1,23 @@
+package hanu
+
+// Middleware wraps a Handler to add cross-cutting behavior (panic
+// recovery, rate limiting, ACLs, logging, ...) without touching command
+// code. Register middleware with Bot.Use; it runs around every command
+// dispatch in registration order, outermost first.
+type Middleware func(Handler) Handler
+
+// Use registers middleware to wrap every command Handler. Middleware
+// registered first runs outermost, so Use(recovery, acl) runs recovery,
+// then acl, then the command itself.
+func (b *Bot) Use(mw ...Middleware) {
+	b.middleware = append(b.middleware, mw...)
+}
+
+// wrap composes the registered middleware around handler, outermost first
+func (b *Bot) wrap(handler Handler) Handler {
+	for i := len(b.middleware) - 1; i >= 0; i-- {
+		handler = b.middleware[i](handler)
+	}
+
+	return handler
+}
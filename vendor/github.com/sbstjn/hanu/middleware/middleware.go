@@ -0,0 +1,117 @@
+// Package middleware ships the cross-cutting hanu.Middleware every
+// production bot ends up writing for itself: panic recovery, per-user
+// rate limiting, an ACL allow-list, and request-scoped logging.
+package middleware
+
+import (
+	"sync"
+	"time"
+
+	"github.com/sbstjn/hanu"
+)
+
+// Recovery stops a panicking Handler from taking down Bot.Listen. The
+// panic is logged and the command simply fails to respond, rather than
+// crashing the whole bot.
+func Recovery(logger hanu.Logger) hanu.Middleware {
+	return func(next hanu.Handler) hanu.Handler {
+		return func(conv hanu.Conversation) {
+			defer func() {
+				if r := recover(); r != nil {
+					logger.Error("panic in command handler", "text", conv.Text(), "panic", r)
+				}
+			}()
+
+			next(conv)
+		}
+	}
+}
+
+// bucket is a per-user token bucket for RateLimit
+type bucket struct {
+	tokens   float64
+	lastFill time.Time
+}
+
+// RateLimit throttles command dispatch per user to one token every rate,
+// up to burst tokens banked. Requests over the limit are silently dropped.
+func RateLimit(rate time.Duration, burst int) hanu.Middleware {
+	var mu sync.Mutex
+	buckets := make(map[string]*bucket)
+
+	return func(next hanu.Handler) hanu.Handler {
+		return func(conv hanu.Conversation) {
+			mu.Lock()
+			allowed := takeToken(buckets, conv.User(), rate, burst)
+			mu.Unlock()
+
+			if !allowed {
+				return
+			}
+
+			next(conv)
+		}
+	}
+}
+
+// takeToken refills b's bucket for elapsed time, then takes one token if
+// available. Callers must hold the map's lock.
+func takeToken(buckets map[string]*bucket, user string, rate time.Duration, burst int) bool {
+	b, ok := buckets[user]
+	if !ok {
+		b = &bucket{tokens: float64(burst), lastFill: time.Now()}
+		buckets[user] = b
+	}
+
+	now := time.Now()
+	b.tokens += now.Sub(b.lastFill).Seconds() / rate.Seconds()
+	if b.tokens > float64(burst) {
+		b.tokens = float64(burst)
+	}
+	b.lastFill = now
+
+	if b.tokens < 1 {
+		return false
+	}
+
+	b.tokens--
+	return true
+}
+
+// ACL only lets commands from users whose ID is literally in allowedIDs
+// through; everyone else is silently ignored. Middleware is backend
+// agnostic, so it has no way to expand a Slack usergroup ID into its
+// members — pass individual user IDs, not group IDs.
+func ACL(allowedIDs ...string) hanu.Middleware {
+	allowed := make(map[string]struct{}, len(allowedIDs))
+	for _, id := range allowedIDs {
+		allowed[id] = struct{}{}
+	}
+
+	return func(next hanu.Handler) hanu.Handler {
+		return func(conv hanu.Conversation) {
+			if _, ok := allowed[conv.User()]; !ok {
+				return
+			}
+
+			next(conv)
+		}
+	}
+}
+
+// Logging emits a structured log line for every command dispatch,
+// including how long the Handler took to run.
+func Logging(logger hanu.Logger) hanu.Middleware {
+	return func(next hanu.Handler) hanu.Handler {
+		return func(conv hanu.Conversation) {
+			start := time.Now()
+			next(conv)
+
+			logger.Info("command dispatched",
+				"user", conv.User(),
+				"text", conv.Text(),
+				"latency_ms", time.Since(start).Milliseconds(),
+			)
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package middleware
+
+import (
+	"testing"
+	"time"
+
+	"github.com/sbstjn/hanu"
+)
+
+type noopLogger struct{}
+
+func (noopLogger) Debug(msg string, kv ...any) {}
+func (noopLogger) Info(msg string, kv ...any)  {}
+func (noopLogger) Warn(msg string, kv ...any)  {}
+func (noopLogger) Error(msg string, kv ...any) {}
+
+func conversationAs(user string) hanu.Conversation {
+	msg := hanu.Message{}
+	msg.SetUser(user)
+
+	noReply := hanu.Sender(func(hanu.Message) error { return nil })
+	return hanu.NewConversation(hanu.Match{}, msg, noReply, nil)
+}
+
+func TestACLAllowsOnlyListedUsers(t *testing.T) {
+	var called []string
+	handler := ACL("U1", "U2")(func(conv hanu.Conversation) {
+		called = append(called, conv.User())
+	})
+
+	handler(conversationAs("U1"))
+	handler(conversationAs("U3"))
+	handler(conversationAs("U2"))
+
+	if len(called) != 2 || called[0] != "U1" || called[1] != "U2" {
+		t.Fatalf("called = %v, want [U1 U2]", called)
+	}
+}
+
+func TestRateLimitAllowsBurstThenThrottles(t *testing.T) {
+	var calls int
+	handler := RateLimit(time.Hour, 2)(func(conv hanu.Conversation) {
+		calls++
+	})
+
+	handler(conversationAs("U1"))
+	handler(conversationAs("U1"))
+	handler(conversationAs("U1"))
+
+	if calls != 2 {
+		t.Fatalf("calls = %d, want 2 (burst of 2, third throttled)", calls)
+	}
+}
+
+func TestRateLimitTracksUsersIndependently(t *testing.T) {
+	var calls int
+	handler := RateLimit(time.Hour, 1)(func(conv hanu.Conversation) {
+		calls++
+	})
+
+	handler(conversationAs("U1"))
+	handler(conversationAs("U2"))
+
+	if calls != 2 {
+		t.Fatalf("calls = %d, want 2 (independent per-user buckets)", calls)
+	}
+}
+
+func TestRecoveryStopsPanicFromEscaping(t *testing.T) {
+	handler := Recovery(noopLogger{})(func(conv hanu.Conversation) {
+		panic("boom")
+	})
+
+	// A bare call would crash the test binary if recovery didn't work.
+	handler(conversationAs("U1"))
+}
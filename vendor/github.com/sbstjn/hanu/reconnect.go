@@ -0,0 +1,122 @@
+package hanu
+
+import (
+	"errors"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+const (
+	minReconnectBackoff = 1 * time.Second
+	maxReconnectBackoff = 30 * time.Second
+
+	outboundQueueSize = 64
+)
+
+// Sender delivers a Message, buffering it if necessary so callers never
+// race a Transport reconnect. Bot.enqueue implements this for Conversation.
+type Sender func(msg Message) error
+
+// reconnectState holds everything Bot needs to manage a resilient
+// Transport connection: an outbound queue so sends survive a reconnect,
+// a mutex guarding concurrent use of the Transport during one, and the
+// registered lifecycle hooks.
+type reconnectState struct {
+	transportMu     sync.RWMutex
+	outbound        chan Message
+	disconnectHooks []func(error)
+	reconnectHooks  []func()
+}
+
+func newReconnectState() *reconnectState {
+	return &reconnectState{
+		outbound: make(chan Message, outboundQueueSize),
+	}
+}
+
+// OnDisconnect registers a hook invoked whenever the Transport connection
+// is lost, before a reconnect is attempted
+func (b *Bot) OnDisconnect(fn func(error)) {
+	b.reconnectState.disconnectHooks = append(b.reconnectState.disconnectHooks, fn)
+}
+
+// OnReconnect registers a hook invoked after the Transport has
+// successfully reconnected
+func (b *Bot) OnReconnect(fn func()) {
+	b.reconnectState.reconnectHooks = append(b.reconnectState.reconnectHooks, fn)
+}
+
+// enqueue buffers msg for delivery by the send loop, so a Conversation
+// reply never races a Transport reconnect
+func (b *Bot) enqueue(msg Message) error {
+	b.reconnectState.outbound <- msg
+	return nil
+}
+
+// sendLoop drains the outbound queue and hands messages to the Transport.
+// It holds a read lock while sending so it waits out an in-progress
+// reconnect rather than sending on a half-torn-down connection.
+func (b *Bot) sendLoop() {
+	for msg := range b.reconnectState.outbound {
+		b.reconnectState.transportMu.RLock()
+		err := b.Transport.Send(msg)
+		b.reconnectState.transportMu.RUnlock()
+
+		if err != nil {
+			b.logger.Error("unable to send message", "error", err)
+		}
+	}
+}
+
+// reconnect tears down and re-establishes the Transport connection with
+// exponential backoff and jitter, capped at maxReconnectBackoff and
+// bounded by MaxReconnectAttempts (0 = infinite).
+//
+// transportMu is held as a writer for the whole attempt, not just the
+// instant Connect() runs: sendLoop only needs a reader lock, so holding
+// it across the backoff sleeps too is what actually makes it block and
+// queue rather than dequeue-and-fail against a torn-down Transport.
+func (b *Bot) reconnect(cause error) error {
+	b.reconnectState.transportMu.Lock()
+	defer b.reconnectState.transportMu.Unlock()
+
+	b.logger.Warn("transport disconnected", "error", cause)
+
+	for _, hook := range b.reconnectState.disconnectHooks {
+		hook(cause)
+	}
+
+	if err := b.Transport.Close(); err != nil {
+		b.logger.Debug("unable to close transport before reconnecting", "error", err)
+	}
+
+	backoff := minReconnectBackoff
+	for attempt := 1; b.MaxReconnectAttempts == 0 || attempt <= b.MaxReconnectAttempts; attempt++ {
+		time.Sleep(backoff + jitter(backoff))
+
+		err := b.Transport.Connect()
+		if err == nil {
+			b.logger.Info("reconnected", "attempt", attempt)
+			for _, hook := range b.reconnectState.reconnectHooks {
+				hook()
+			}
+			return nil
+		}
+
+		b.logger.Warn("reconnect attempt failed", "attempt", attempt, "error", err)
+
+		backoff *= 2
+		if backoff > maxReconnectBackoff {
+			backoff = maxReconnectBackoff
+		}
+	}
+
+	return errors.New("hanu: exceeded MaxReconnectAttempts while reconnecting")
+}
+
+// jitter returns a random duration in [0, backoff/2], to keep many bots
+// reconnecting after the same outage from hammering the backend in lockstep
+func jitter(backoff time.Duration) time.Duration {
+	return time.Duration(rand.Int63n(int64(backoff)/2 + 1))
+}
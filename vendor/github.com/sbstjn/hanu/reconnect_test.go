@@ -0,0 +1,105 @@
+package hanu
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+// TestJitterWithinBounds checks jitter never returns a negative value or
+// one more than half the backoff (allowing for the +1 in its bound).
+func TestJitterWithinBounds(t *testing.T) {
+	backoffs := []time.Duration{minReconnectBackoff, 4 * time.Second, maxReconnectBackoff}
+
+	for _, backoff := range backoffs {
+		for i := 0; i < 100; i++ {
+			j := jitter(backoff)
+			if j < 0 || j > backoff/2+1 {
+				t.Fatalf("jitter(%s) = %s, want in [0, %s]", backoff, j, backoff/2+1)
+			}
+		}
+	}
+}
+
+// fakeTransport is a Transport whose Connect blocks until unblockConnect
+// is closed, so reconnect()'s backoff window can be held open on demand.
+type fakeTransport struct {
+	mu             sync.Mutex
+	connected      bool
+	sent           []Message
+	unblockConnect chan struct{}
+}
+
+func (f *fakeTransport) Connect() error {
+	<-f.unblockConnect
+
+	f.mu.Lock()
+	f.connected = true
+	f.mu.Unlock()
+
+	return nil
+}
+
+func (f *fakeTransport) Send(msg Message) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if !f.connected {
+		return errDisconnected
+	}
+
+	f.sent = append(f.sent, msg)
+	return nil
+}
+
+func (f *fakeTransport) Receive() (Message, error) { return Message{}, errDisconnected }
+func (f *fakeTransport) Close() error              { return nil }
+
+var errDisconnected = &transportError{"transport not connected"}
+
+type transportError struct{ msg string }
+
+func (e *transportError) Error() string { return e.msg }
+
+// TestSendLoopQueuesThroughReconnect verifies a message enqueued while the
+// Transport is down is delivered once reconnect() succeeds, instead of
+// being dequeued and dropped against the torn-down Transport mid-backoff.
+func TestSendLoopQueuesThroughReconnect(t *testing.T) {
+	ft := &fakeTransport{unblockConnect: make(chan struct{})}
+	b := &Bot{
+		Transport:      ft,
+		reconnectState: newReconnectState(),
+		logger:         stdLogger{},
+	}
+
+	go b.sendLoop()
+
+	reconnectDone := make(chan error, 1)
+	go func() { reconnectDone <- b.reconnect(errDisconnected) }()
+
+	// Give reconnect() a moment to grab transportMu before we enqueue, so
+	// the message is genuinely in flight during the backoff window.
+	time.Sleep(10 * time.Millisecond)
+	if err := b.enqueue(Message{Type: "message"}); err != nil {
+		t.Fatalf("enqueue: %v", err)
+	}
+
+	close(ft.unblockConnect)
+	if err := <-reconnectDone; err != nil {
+		t.Fatalf("reconnect: %v", err)
+	}
+
+	// sendLoop drains asynchronously; poll briefly for delivery.
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		ft.mu.Lock()
+		n := len(ft.sent)
+		ft.mu.Unlock()
+		if n == 1 {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	t.Fatal("message enqueued during reconnect was never delivered")
+}
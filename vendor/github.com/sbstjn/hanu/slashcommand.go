@@ -0,0 +1,50 @@
+package hanu
+
+import "encoding/json"
+
+// SlashCommand is a `/command` invocation, normalized the same way
+// across every Transport that supports slash commands.
+type SlashCommand struct {
+	Command     string `json:"command"`
+	Text        string `json:"text"`
+	UserID      string `json:"user_id"`
+	ChannelID   string `json:"channel_id"`
+	ResponseURL string `json:"response_url"`
+}
+
+// SlashCommandHandler handles a SlashCommand dispatched by a Transport
+type SlashCommandHandler func(cmd SlashCommand)
+
+// InteractiveHandler handles a raw interactive payload (block actions,
+// view submissions, shortcuts, ...) that a Transport doesn't already
+// recognize and dispatch itself via BlockActionRegistrar or
+// ViewSubmissionRegistrar.
+type InteractiveHandler func(payload json.RawMessage)
+
+// SlashCommandRegistrar is implemented by a Transport that can dispatch
+// `/command` slash commands
+type SlashCommandRegistrar interface {
+	SlashCommand(cmd string, handler SlashCommandHandler)
+}
+
+// InteractiveHandlerRegistrar is implemented by a Transport that can
+// dispatch raw interactive payloads, keyed by their own `type` field
+type InteractiveHandlerRegistrar interface {
+	InteractiveHandler(kind string, handler InteractiveHandler)
+}
+
+// SlashCommand registers handler for a `/command` slash command, if the
+// Bot's Transport supports them
+func (b *Bot) SlashCommand(cmd string, handler SlashCommandHandler) {
+	if r, ok := b.Transport.(SlashCommandRegistrar); ok {
+		r.SlashCommand(cmd, handler)
+	}
+}
+
+// InteractiveHandler registers handler for a raw interactive payload of
+// the given kind, if the Bot's Transport supports it
+func (b *Bot) InteractiveHandler(kind string, handler InteractiveHandler) {
+	if r, ok := b.Transport.(InteractiveHandlerRegistrar); ok {
+		r.InteractiveHandler(kind, handler)
+	}
+}
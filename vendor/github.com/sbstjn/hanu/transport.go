@@ -0,0 +1,31 @@
+package hanu
+
+// Transport abstracts the chat backend a Bot talks to. Everything above
+// this interface — command registration, help generation, mention
+// stripping, Conversation replies — is backend agnostic; only Connect,
+// Send and Receive know about the wire format of a particular chat
+// service. See the transport/slack, transport/mattermost and
+// transport/rocketchat sub-packages for concrete implementations.
+type Transport interface {
+	// Connect establishes the underlying connection (handshake, auth, ...)
+	Connect() error
+
+	// Send delivers a Message to the backend
+	Send(msg Message) error
+
+	// Receive blocks until the next Message arrives, or returns an error
+	// if the connection is lost
+	Receive() (Message, error)
+
+	// Close tears down the underlying connection
+	Close() error
+}
+
+// Identifier is implemented by a Transport that can report the bot's own
+// user ID after Connect, for backends (like Slack's Socket Mode) whose
+// handshake doesn't hand it over for free the way rtm.start used to.
+// Bot.New calls this right after Connect to populate Bot.ID, which
+// Message.IsRelevantFor and Message.StripMention both key off.
+type Identifier interface {
+	BotUserID() (string, error)
+}
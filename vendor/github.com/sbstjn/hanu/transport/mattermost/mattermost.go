@@ -0,0 +1,168 @@
+// Package mattermost implements hanu.Transport on top of the Mattermost
+// WebSocket API v4.
+package mattermost
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/sbstjn/hanu"
+	"golang.org/x/net/websocket"
+)
+
+// event is the envelope Mattermost sends over `/api/v4/websocket`
+type event struct {
+	Event     string            `json:"event"`
+	Data      map[string]string `json:"data"`
+	Broadcast struct {
+		ChannelID string `json:"channel_id"`
+	} `json:"broadcast"`
+}
+
+// postData is the JSON-encoded string found in event.Data["post"] for a
+// `posted` event
+type postData struct {
+	ID        string `json:"id"`
+	ChannelID string `json:"channel_id"`
+	UserID    string `json:"user_id"`
+	Message   string `json:"message"`
+}
+
+// Transport implements hanu.Transport on top of the Mattermost WebSocket API
+type Transport struct {
+	Socket    *websocket.Conn
+	ServerURL string
+	Token     string
+
+	seq uint64
+}
+
+// New creates a Mattermost transport. serverURL is the Mattermost site URL
+// (e.g. `wss://chat.example.com`), token is a personal access token or bot
+// token with permission to read and post in the target channels.
+func New(serverURL, token string) *Transport {
+	return &Transport{
+		ServerURL: serverURL,
+		Token:     token,
+	}
+}
+
+// Connect dials `/api/v4/websocket` and authenticates with the bot token
+func (t *Transport) Connect() error {
+	config, err := websocket.NewConfig(t.ServerURL+"/api/v4/websocket", t.ServerURL)
+	if err != nil {
+		return fmt.Errorf("Failed to create config for Websocket: %v", err)
+	}
+
+	t.Socket, err = websocket.DialConfig(config)
+	if err != nil {
+		return fmt.Errorf("Failed to connect to Websocket: %v", err)
+	}
+
+	t.seq++
+	auth := map[string]interface{}{
+		"seq":    t.seq,
+		"action": "authentication_challenge",
+		"data": map[string]string{
+			"token": t.Token,
+		},
+	}
+	if err := websocket.JSON.Send(t.Socket, auth); err != nil {
+		return fmt.Errorf("Failed to authenticate with Mattermost: %v", err)
+	}
+
+	return nil
+}
+
+// Send posts a Message back to its channel. The websocket API is
+// receive-only for posts — there's no client-to-server `post` action —
+// so this goes through the REST API instead.
+func (t *Transport) Send(msg hanu.Message) error {
+	body, err := json.Marshal(map[string]string{
+		"channel_id": msg.Channel(),
+		"message":    msg.Text(),
+	})
+	if err != nil {
+		return fmt.Errorf("Failed to marshal post: %v", err)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, t.restURL()+"/api/v4/posts", bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("Failed to build posts request: %v", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+t.Token)
+	req.Header.Set("Content-Type", "application/json")
+
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("Failed to call /api/v4/posts: %v", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode >= 300 {
+		respBody, _ := ioutil.ReadAll(res.Body)
+		return fmt.Errorf("/api/v4/posts failed with HTTP %d: %s", res.StatusCode, respBody)
+	}
+
+	return nil
+}
+
+// restURL derives the REST API base URL from ServerURL, which is
+// documented (and dialed) as a websocket URL, by swapping its ws/wss
+// scheme for the http/https equivalent.
+func (t *Transport) restURL() string {
+	switch {
+	case strings.HasPrefix(t.ServerURL, "wss://"):
+		return "https://" + strings.TrimPrefix(t.ServerURL, "wss://")
+	case strings.HasPrefix(t.ServerURL, "ws://"):
+		return "http://" + strings.TrimPrefix(t.ServerURL, "ws://")
+	default:
+		return t.ServerURL
+	}
+}
+
+// Receive blocks for the next `posted` event and normalizes it into a
+// hanu.Message
+func (t *Transport) Receive() (hanu.Message, error) {
+	for {
+		var evt event
+		if err := websocket.JSON.Receive(t.Socket, &evt); err != nil {
+			return hanu.Message{}, err
+		}
+
+		if evt.Event != "posted" {
+			continue
+		}
+
+		var post postData
+		if err := json.Unmarshal([]byte(evt.Data["post"]), &post); err != nil {
+			continue
+		}
+
+		msg := hanu.Message{Type: "message"}
+		msg.SetChannel(post.ChannelID)
+		msg.SetUser(post.UserID)
+		msg.SetText(stripMentionMarkup(post.Message))
+
+		return msg, nil
+	}
+}
+
+// Close tears down the websocket connection
+func (t *Transport) Close() error {
+	return t.Socket.Close()
+}
+
+// mentionMarkup matches Mattermost's plain `@username` mention markup
+var mentionMarkup = regexp.MustCompile(`@[a-z0-9._-]+`)
+
+// stripMentionMarkup removes Mattermost's plain `@username` mention markup,
+// the equivalent of Slack's `<@ID>` stripping for this backend
+func stripMentionMarkup(text string) string {
+	return strings.TrimSpace(mentionMarkup.ReplaceAllString(text, ""))
+}
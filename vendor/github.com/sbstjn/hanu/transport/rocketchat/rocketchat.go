@@ -0,0 +1,169 @@
+// Package rocketchat implements hanu.Transport on top of Rocket.Chat's
+// Realtime API, which runs DDP (the Meteor data protocol) over a websocket.
+package rocketchat
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/sbstjn/hanu"
+	"golang.org/x/net/websocket"
+)
+
+// mentionMarkup matches Rocket.Chat's plain `@username` mention markup
+var mentionMarkup = regexp.MustCompile(`@[a-zA-Z0-9._-]+`)
+
+// stripMentionMarkup removes Rocket.Chat's plain `@username` mention
+// markup, the equivalent of Slack's `<@ID>` stripping for this backend
+func stripMentionMarkup(text string) string {
+	return strings.TrimSpace(mentionMarkup.ReplaceAllString(text, ""))
+}
+
+// ddpMessage is a generic DDP envelope; Rocket.Chat's `stream-room-messages`
+// subscription delivers new messages as `changed` messages
+type ddpMessage struct {
+	Msg        string           `json:"msg"`
+	Collection string           `json:"collection,omitempty"`
+	ID         string           `json:"id,omitempty"`
+	Method     string           `json:"method,omitempty"`
+	Params     []interface{}    `json:"params,omitempty"`
+	Fields     ddpChangedFields `json:"fields,omitempty"`
+}
+
+type ddpChangedFields struct {
+	EventName string            `json:"eventName"`
+	Args      []json.RawMessage `json:"args"`
+}
+
+// Transport implements hanu.Transport on top of Rocket.Chat's Realtime API
+type Transport struct {
+	Socket    *websocket.Conn
+	ServerURL string
+	AuthToken string
+	UserID    string
+	RoomID    string
+
+	callID uint64
+}
+
+// New creates a Rocket.Chat transport. serverURL is the Rocket.Chat
+// websocket endpoint (e.g. `wss://chat.example.com/websocket`), authToken
+// and userID come from a personal access token login, roomID is the
+// channel to subscribe to via `stream-room-messages`.
+func New(serverURL, authToken, userID, roomID string) *Transport {
+	return &Transport{
+		ServerURL: serverURL,
+		AuthToken: authToken,
+		UserID:    userID,
+		RoomID:    roomID,
+	}
+}
+
+// Connect dials the Realtime API, logs in with the resume token, and
+// subscribes to the configured room's `stream-room-messages`
+func (t *Transport) Connect() error {
+	config, err := websocket.NewConfig(t.ServerURL, t.ServerURL)
+	if err != nil {
+		return fmt.Errorf("Failed to create config for Websocket: %v", err)
+	}
+
+	t.Socket, err = websocket.DialConfig(config)
+	if err != nil {
+		return fmt.Errorf("Failed to connect to Websocket: %v", err)
+	}
+
+	connect := map[string]interface{}{
+		"msg":     "connect",
+		"version": "1",
+		"support": []string{"1"},
+	}
+	if err := websocket.JSON.Send(t.Socket, connect); err != nil {
+		return fmt.Errorf("Failed to send DDP connect: %v", err)
+	}
+
+	t.callID++
+	login := map[string]interface{}{
+		"msg":    "method",
+		"method": "login",
+		"id":     fmt.Sprintf("%d", t.callID),
+		"params": []interface{}{map[string]interface{}{
+			"resume": t.AuthToken,
+		}},
+	}
+	if err := websocket.JSON.Send(t.Socket, login); err != nil {
+		return fmt.Errorf("Failed to authenticate with Rocket.Chat: %v", err)
+	}
+
+	t.callID++
+	sub := map[string]interface{}{
+		"msg":    "sub",
+		"id":     fmt.Sprintf("%d", t.callID),
+		"name":   "stream-room-messages",
+		"params": []interface{}{t.RoomID, false},
+	}
+	return websocket.JSON.Send(t.Socket, sub)
+}
+
+// Send posts a Message to the subscribed room via the `sendMessage` method
+func (t *Transport) Send(msg hanu.Message) error {
+	t.callID++
+	call := map[string]interface{}{
+		"msg":    "method",
+		"method": "sendMessage",
+		"id":     fmt.Sprintf("%d", t.callID),
+		"params": []interface{}{map[string]interface{}{
+			"rid": msg.Channel(),
+			"msg": msg.Text(),
+		}},
+	}
+
+	return websocket.JSON.Send(t.Socket, call)
+}
+
+// Receive blocks for the next `stream-room-messages` changed event and
+// normalizes it into a hanu.Message
+func (t *Transport) Receive() (hanu.Message, error) {
+	for {
+		var frame ddpMessage
+		if err := websocket.JSON.Receive(t.Socket, &frame); err != nil {
+			return hanu.Message{}, err
+		}
+
+		switch frame.Msg {
+		case "ping":
+			websocket.JSON.Send(t.Socket, ddpMessage{Msg: "pong"})
+			continue
+		case "changed":
+			if frame.Collection != "stream-room-messages" || len(frame.Fields.Args) == 0 {
+				continue
+			}
+		default:
+			continue
+		}
+
+		var posted struct {
+			RID string `json:"rid"`
+			Msg string `json:"msg"`
+			U   struct {
+				ID string `json:"_id"`
+			} `json:"u"`
+		}
+		if err := json.Unmarshal(frame.Fields.Args[0], &posted); err != nil {
+			continue
+		}
+
+		msg := hanu.Message{Type: "message"}
+		msg.SetChannel(posted.RID)
+		msg.SetUser(posted.U.ID)
+		msg.SetText(stripMentionMarkup(posted.Msg))
+
+		return msg, nil
+	}
+}
+
+// Close tears down the websocket connection
+func (t *Transport) Close() error {
+	return t.Socket.Close()
+}
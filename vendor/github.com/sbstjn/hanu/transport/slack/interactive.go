@@ -0,0 +1,249 @@
+package slack
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/sbstjn/hanu"
+)
+
+// requestTimestampTolerance bounds how old an X-Slack-Request-Timestamp
+// may be, guarding against replayed interactivity requests
+const requestTimestampTolerance = 5 * time.Minute
+
+// blockActionsPayload is the `payload` field of an `interactive` envelope
+// (or the HTTP `/slack/interactive` body) when Type is `block_actions`
+type blockActionsPayload struct {
+	Type      string `json:"type"`
+	TriggerID string `json:"trigger_id"`
+	User      struct {
+		ID string `json:"id"`
+	} `json:"user"`
+	Actions []blockAction `json:"actions"`
+}
+
+// blockAction is one element of a block_actions payload's `actions` list:
+// a button, or a static/external select menu.
+type blockAction struct {
+	ActionID       string `json:"action_id"`
+	Value          string `json:"value"`
+	SelectedOption struct {
+		Value string `json:"value"`
+	} `json:"selected_option"`
+}
+
+// value returns the action's selection: buttons carry it in the
+// top-level `value` field, static/external selects nest it under
+// `selected_option.value`.
+func (a blockAction) value() string {
+	if a.SelectedOption.Value != "" {
+		return a.SelectedOption.Value
+	}
+	return a.Value
+}
+
+// viewSubmissionPayload is the `payload` field of an `interactive`
+// envelope when Type is `view_submission`
+type viewSubmissionPayload struct {
+	Type string `json:"type"`
+	User struct {
+		ID string `json:"id"`
+	} `json:"user"`
+	View struct {
+		CallbackID string `json:"callback_id"`
+		State      struct {
+			Values map[string]map[string]struct {
+				Value string `json:"value"`
+			} `json:"values"`
+		} `json:"state"`
+	} `json:"view"`
+}
+
+type viewsOpenResponse struct {
+	Ok    bool   `json:"ok"`
+	Error string `json:"error"`
+}
+
+// OnBlockAction registers handler for a Block Kit button or select menu
+// with the given action_id
+func (t *Transport) OnBlockAction(actionID string, handler func(hanu.ActionContext)) {
+	t.blockActionHandlers[actionID] = handler
+}
+
+// OnViewSubmission registers handler for a modal's view_submission with
+// the given callback_id
+func (t *Transport) OnViewSubmission(callbackID string, handler func(hanu.ViewSubmissionContext)) {
+	t.viewSubmissionHandlers[callbackID] = handler
+}
+
+// dispatchInteractive routes a decoded interactive payload (received over
+// Socket Mode or the HTTP interactivity listener) to the right handler
+func (t *Transport) dispatchInteractive(raw json.RawMessage) {
+	var payload interactivePayload
+	if err := json.Unmarshal(raw, &payload); err != nil {
+		t.logger.Error("unable to unmarshal interactive payload", "error", err)
+		return
+	}
+
+	switch payload.Type {
+	case "block_actions":
+		t.dispatchBlockActions(raw)
+	case "view_submission":
+		t.dispatchViewSubmission(raw)
+	default:
+		if handler, ok := t.interactiveHandlers[payload.Type]; ok {
+			t.safeGo("interactive:"+payload.Type, func() { handler(raw) })
+		}
+	}
+}
+
+func (t *Transport) dispatchBlockActions(raw json.RawMessage) {
+	var payload blockActionsPayload
+	if err := json.Unmarshal(raw, &payload); err != nil {
+		t.logger.Error("unable to unmarshal block_actions payload", "error", err)
+		return
+	}
+
+	for _, action := range payload.Actions {
+		handler, ok := t.blockActionHandlers[action.ActionID]
+		if !ok {
+			continue
+		}
+
+		ctx := hanu.NewActionContext(action.ActionID, payload.TriggerID, payload.User.ID, action.value(), t.Send, t.OpenModal)
+		t.safeGo("block_action:"+action.ActionID, func() { handler(ctx) })
+	}
+}
+
+func (t *Transport) dispatchViewSubmission(raw json.RawMessage) {
+	var payload viewSubmissionPayload
+	if err := json.Unmarshal(raw, &payload); err != nil {
+		t.logger.Error("unable to unmarshal view_submission payload", "error", err)
+		return
+	}
+
+	handler, ok := t.viewSubmissionHandlers[payload.View.CallbackID]
+	if !ok {
+		return
+	}
+
+	values := make(map[string]string)
+	for _, block := range payload.View.State.Values {
+		for actionID, v := range block {
+			values[actionID] = v.Value
+		}
+	}
+
+	ctx := hanu.ViewSubmissionContext{
+		CallbackID: payload.View.CallbackID,
+		User:       payload.User.ID,
+		Values:     values,
+	}
+	t.safeGo("view_submission:"+payload.View.CallbackID, func() { handler(ctx) })
+}
+
+// OpenModal opens view for triggerID via the `views.open` Web API method.
+// RTM had no way to deliver interactivity at all, so this (like Socket
+// Mode itself) is only reachable once a bot has moved off it.
+func (t *Transport) OpenModal(triggerID string, view hanu.View) error {
+	body, err := json.Marshal(map[string]interface{}{
+		"trigger_id": triggerID,
+		"view":       view,
+	})
+	if err != nil {
+		return fmt.Errorf("Failed to marshal view: %v", err)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, "https://slack.com/api/views.open", bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("Failed to build views.open request: %v", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+t.Token)
+	req.Header.Set("Content-Type", "application/json")
+
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("Failed to call views.open: %v", err)
+	}
+	defer res.Body.Close()
+
+	respBody, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return fmt.Errorf("Failed to read views.open response: %v", err)
+	}
+
+	var response viewsOpenResponse
+	if err := json.Unmarshal(respBody, &response); err != nil {
+		return fmt.Errorf("Failed to unmarshal views.open response: %s", respBody)
+	}
+	if !response.Ok {
+		return fmt.Errorf("views.open failed: %s", response.Error)
+	}
+
+	return nil
+}
+
+// InteractivityHandler is an alternative to Socket Mode `interactive`
+// envelopes: an http.Handler for Slack's classic `/slack/interactive`
+// request URL, for deployments that'd rather terminate interactivity over
+// HTTP than multiplex it onto the Socket Mode connection.
+func (t *Transport) InteractivityHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
+
+		if !t.verifySignature(r.Header, body) {
+			http.Error(w, "invalid signature", http.StatusUnauthorized)
+			return
+		}
+
+		r.Body = ioutil.NopCloser(bytes.NewReader(body))
+		if err := r.ParseForm(); err != nil {
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
+
+		payload := r.PostForm.Get("payload")
+		t.dispatchInteractive(json.RawMessage(payload))
+		w.WriteHeader(http.StatusOK)
+	})
+}
+
+// verifySignature checks Slack's `X-Slack-Signature` against an HMAC of
+// the raw request body, per Slack's request signing scheme. If
+// SigningSecret isn't set, verification is skipped (e.g. local dev
+// against a tunnel Slack can't reach anyway).
+func (t *Transport) verifySignature(header http.Header, body []byte) bool {
+	if t.SigningSecret == "" {
+		return true
+	}
+
+	timestamp := header.Get("X-Slack-Request-Timestamp")
+	ts, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return false
+	}
+	if math.Abs(time.Since(time.Unix(ts, 0)).Seconds()) > requestTimestampTolerance.Seconds() {
+		t.logger.Warn("rejecting interactivity request with stale timestamp", "timestamp", timestamp)
+		return false
+	}
+
+	mac := hmac.New(sha256.New, []byte(t.SigningSecret))
+	mac.Write([]byte("v0:" + timestamp + ":"))
+	mac.Write(body)
+	expected := "v0=" + hex.EncodeToString(mac.Sum(nil))
+
+	return hmac.Equal([]byte(expected), []byte(header.Get("X-Slack-Signature")))
+}
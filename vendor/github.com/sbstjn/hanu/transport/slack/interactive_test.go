@@ -0,0 +1,91 @@
+package slack
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte("v0:" + timestamp + ":"))
+	mac.Write(body)
+	return "v0=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func TestVerifySignatureSkippedWithoutSecret(t *testing.T) {
+	tr := New("xoxb-test", "xapp-test")
+
+	if !tr.verifySignature(http.Header{}, []byte("anything")) {
+		t.Fatal("verifySignature should pass through when SigningSecret is unset")
+	}
+}
+
+func TestVerifySignatureAcceptsValidRequest(t *testing.T) {
+	tr := New("xoxb-test", "xapp-test")
+	tr.SigningSecret = "shh"
+
+	body := []byte(`payload={"type":"block_actions"}`)
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+
+	header := http.Header{}
+	header.Set("X-Slack-Request-Timestamp", timestamp)
+	header.Set("X-Slack-Signature", sign(tr.SigningSecret, timestamp, body))
+
+	if !tr.verifySignature(header, body) {
+		t.Fatal("verifySignature rejected a correctly signed request")
+	}
+}
+
+func TestVerifySignatureRejectsWrongSecret(t *testing.T) {
+	tr := New("xoxb-test", "xapp-test")
+	tr.SigningSecret = "shh"
+
+	body := []byte(`payload={"type":"block_actions"}`)
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+
+	header := http.Header{}
+	header.Set("X-Slack-Request-Timestamp", timestamp)
+	header.Set("X-Slack-Signature", sign("wrong-secret", timestamp, body))
+
+	if tr.verifySignature(header, body) {
+		t.Fatal("verifySignature accepted a request signed with the wrong secret")
+	}
+}
+
+func TestVerifySignatureRejectsStaleTimestamp(t *testing.T) {
+	tr := New("xoxb-test", "xapp-test")
+	tr.SigningSecret = "shh"
+
+	body := []byte(`payload={"type":"block_actions"}`)
+	timestamp := strconv.FormatInt(time.Now().Add(-time.Hour).Unix(), 10)
+
+	header := http.Header{}
+	header.Set("X-Slack-Request-Timestamp", timestamp)
+	header.Set("X-Slack-Signature", sign(tr.SigningSecret, timestamp, body))
+
+	if tr.verifySignature(header, body) {
+		t.Fatal("verifySignature accepted a request with a stale timestamp")
+	}
+}
+
+func TestVerifySignatureRejectsTamperedBody(t *testing.T) {
+	tr := New("xoxb-test", "xapp-test")
+	tr.SigningSecret = "shh"
+
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	signature := sign(tr.SigningSecret, timestamp, []byte(`payload={"type":"block_actions"}`))
+
+	header := http.Header{}
+	header.Set("X-Slack-Request-Timestamp", timestamp)
+	header.Set("X-Slack-Signature", signature)
+
+	tampered := []byte(`payload={"type":"view_submission"}`)
+	if tr.verifySignature(header, tampered) {
+		t.Fatal("verifySignature accepted a body that doesn't match the signature")
+	}
+}
@@ -0,0 +1,431 @@
+// Package slack implements hanu.Transport on top of Slack's Socket Mode
+// Events API, replacing the deprecated rtm.start connection.
+package slack
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/sbstjn/hanu"
+	"golang.org/x/net/websocket"
+)
+
+// noopLogger is used until SetLogger is called, so Transport never has to
+// nil-check its logger
+type noopLogger struct{}
+
+func (noopLogger) Debug(msg string, kv ...any) {}
+func (noopLogger) Info(msg string, kv ...any)  {}
+func (noopLogger) Warn(msg string, kv ...any)  {}
+func (noopLogger) Error(msg string, kv ...any) {}
+
+// envelope types sent by Slack over the Socket Mode connection
+const (
+	envelopeTypeHello         = "hello"
+	envelopeTypeDisconnect    = "disconnect"
+	envelopeTypeEventsAPI     = "events_api"
+	envelopeTypeInteractive   = "interactive"
+	envelopeTypeSlashCommands = "slash_commands"
+)
+
+type connectionsOpenResponse struct {
+	Ok    bool   `json:"ok"`
+	Error string `json:"error"`
+	URL   string `json:"url"`
+}
+
+// authTestResponse is the response body of `auth.test`, used to resolve
+// the bot's own user ID since `apps.connections.open` doesn't return one
+// the way `rtm.start` used to
+type authTestResponse struct {
+	Ok     bool   `json:"ok"`
+	Error  string `json:"error"`
+	UserID string `json:"user_id"`
+}
+
+// socketModeEnvelope is the outer frame Slack wraps every Socket Mode
+// payload in. EnvelopeID must be echoed back in an ack for any envelope
+// that carries one.
+type socketModeEnvelope struct {
+	EnvelopeID string          `json:"envelope_id"`
+	Type       string          `json:"type"`
+	Payload    json.RawMessage `json:"payload"`
+}
+
+type socketModeAck struct {
+	EnvelopeID string `json:"envelope_id"`
+}
+
+// eventsAPIPayload is the `payload` field of an `events_api` envelope. The
+// `event` sub-object for `app_mention` and `message` (including `message.im`
+// direct messages) is wire-compatible with hanu.Message, so it's decoded
+// straight into it.
+type eventsAPIPayload struct {
+	Event json.RawMessage `json:"event"`
+}
+
+// interactivePayload is the `payload` field of an `interactive` envelope
+type interactivePayload struct {
+	Type string `json:"type"`
+}
+
+// Transport implements hanu.Transport on top of Slack's Socket Mode API
+type Transport struct {
+	Socket   *websocket.Conn
+	Token    string
+	AppToken string
+
+	// SigningSecret verifies requests to InteractivityHandler came from
+	// Slack. If it is empty, the HTTP interactivity listener skips
+	// verification and accepts every request. Socket Mode envelopes are
+	// already authenticated by the websocket connection itself, so it's
+	// unused there.
+	SigningSecret string
+
+	// socketMu guards Socket against Connect reassigning it (on initial
+	// dial or Slack-driven reconnect) while Send is in flight from a
+	// block action handler's goroutine.
+	socketMu sync.RWMutex
+
+	slashCommands          map[string]hanu.SlashCommandHandler
+	interactiveHandlers    map[string]hanu.InteractiveHandler
+	blockActionHandlers    map[string]func(hanu.ActionContext)
+	viewSubmissionHandlers map[string]func(hanu.ViewSubmissionContext)
+	pong                   chan struct{}
+	pingStop               chan struct{}
+	logger                 hanu.Logger
+
+	botUserID string
+}
+
+// SetLogger installs logger for handshake, ping/pong and disconnect events.
+// hanu.Bot calls this automatically if set via Bot.SetLogger.
+func (t *Transport) SetLogger(logger hanu.Logger) {
+	t.logger = logger
+}
+
+// New creates a Slack Socket Mode transport. token is the bot token
+// (`xoxb-...`) used for calling the Slack Web API, appToken is the
+// app-level token (`xapp-...`) used to open the Socket Mode connection.
+func New(token, appToken string) *Transport {
+	return &Transport{
+		Token:    token,
+		AppToken: appToken,
+
+		slashCommands:          make(map[string]hanu.SlashCommandHandler),
+		interactiveHandlers:    make(map[string]hanu.InteractiveHandler),
+		blockActionHandlers:    make(map[string]func(hanu.ActionContext)),
+		viewSubmissionHandlers: make(map[string]func(hanu.ViewSubmissionContext)),
+		logger:                 noopLogger{},
+	}
+}
+
+// Connect opens a Socket Mode connection via `apps.connections.open`
+// and dials the returned WebSocket URL
+func (t *Transport) Connect() error {
+	dialer := &net.Dialer{
+		Timeout:   30 * time.Second,
+		KeepAlive: 30 * time.Second,
+	}
+	transport := &http.Transport{
+		Dial:                dialer.Dial,
+		TLSHandshakeTimeout: 15 * time.Second,
+	}
+	client := &http.Client{
+		Transport: transport,
+		Timeout:   30 * time.Second,
+	}
+
+	req, err := http.NewRequest(http.MethodPost, "https://slack.com/api/apps.connections.open", bytes.NewReader(nil))
+	if err != nil {
+		return fmt.Errorf("Failed to build apps.connections.open request: %v", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+t.AppToken)
+
+	// Check for HTTP error on connection
+	res, err := client.Do(req)
+	if err != nil {
+		return errors.New("Failed to connect to Slack apps.connections.open API")
+	}
+	defer res.Body.Close()
+
+	// Check for HTTP status code
+	if res.StatusCode != 200 {
+		return fmt.Errorf("Failed with HTTP Code: %d", res.StatusCode)
+	}
+
+	// Read response body
+	body, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return fmt.Errorf("Failed to read body from response")
+	}
+
+	// Parse response
+	var response connectionsOpenResponse
+	if err := json.Unmarshal(body, &response); err != nil {
+		return fmt.Errorf("Failed to unmarshal JSON: %s", body)
+	}
+
+	// Check for Slack error
+	if !response.Ok {
+		return errors.New(response.Error)
+	}
+
+	// Connect to websocket
+	config, err := websocket.NewConfig(response.URL, "https://api.slack.com/")
+	if err != nil {
+		return fmt.Errorf("Failed to create config for Websocket: %v", err)
+	}
+	config.Dialer = dialer
+	socket, err := websocket.DialConfig(config)
+	if err != nil {
+		return fmt.Errorf("Failed to connect to Websocket: %v", err)
+	}
+
+	t.socketMu.Lock()
+	t.Socket = socket
+	t.socketMu.Unlock()
+
+	t.logger.Info("socket mode handshake succeeded")
+
+	// Slack sends `disconnect` envelopes periodically during normal
+	// operation, each driving a Connect() call here from inside Receive.
+	// Stop the ping goroutine from the previous connection before
+	// starting a new one, or they pile up and all race for the same
+	// t.pong, spuriously timing out and killing the fresh connection.
+	if t.pingStop != nil {
+		close(t.pingStop)
+	}
+	t.pingStop = make(chan struct{})
+	t.pong = make(chan struct{})
+	go t.ping(t.pingStop)
+
+	return nil
+}
+
+// ping sends a periodic keepalive ping and closes the socket if no pong
+// is seen within a minute, forcing Receive to surface an error the caller
+// can reconnect on. It exits without touching the socket if stop is
+// closed first, so a superseded connection's pinger doesn't fight the
+// one that replaced it.
+func (t *Transport) ping(stop chan struct{}) {
+	select {
+	case <-time.After(5 * time.Second):
+	case <-stop:
+		return
+	}
+
+	count := uint64(100)
+	for {
+		count++
+		if err := t.Send(hanu.Message{ID: count, Type: "ping"}); err != nil {
+			t.logger.Error("unable to send a ping to the Slack API", "error", err)
+			if err := t.Close(); err != nil {
+				t.logger.Error("unable to close websocket", "error", err)
+			}
+			return
+		}
+		t.logger.Debug("ping sent", "id", count)
+
+		select {
+		case <-t.pong:
+			t.logger.Debug("pong received", "id", count)
+			select {
+			case <-time.After(time.Minute):
+			case <-stop:
+				return
+			}
+		case <-time.After(time.Minute):
+			t.logger.Warn("waited more than a minute for a pong, exiting")
+			if err := t.Close(); err != nil {
+				t.logger.Error("unable to close websocket", "error", err)
+			}
+			return
+		case <-stop:
+			return
+		}
+	}
+}
+
+// BotUserID resolves and caches the bot's own user ID via `auth.test`,
+// satisfying hanu.Identifier so Bot.New can populate Bot.ID.
+func (t *Transport) BotUserID() (string, error) {
+	if t.botUserID != "" {
+		return t.botUserID, nil
+	}
+
+	req, err := http.NewRequest(http.MethodPost, "https://slack.com/api/auth.test", bytes.NewReader(nil))
+	if err != nil {
+		return "", fmt.Errorf("Failed to build auth.test request: %v", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+t.Token)
+
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("Failed to call auth.test: %v", err)
+	}
+	defer res.Body.Close()
+
+	body, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return "", fmt.Errorf("Failed to read auth.test response: %v", err)
+	}
+
+	var response authTestResponse
+	if err := json.Unmarshal(body, &response); err != nil {
+		return "", fmt.Errorf("Failed to unmarshal auth.test response: %s", body)
+	}
+	if !response.Ok {
+		return "", errors.New(response.Error)
+	}
+
+	t.botUserID = response.UserID
+	return t.botUserID, nil
+}
+
+// Send delivers a Message over the Socket Mode connection. Safe to call
+// concurrently with a Connect-driven reconnect, including from a block
+// action handler's own goroutine.
+func (t *Transport) Send(msg hanu.Message) error {
+	t.socketMu.RLock()
+	defer t.socketMu.RUnlock()
+
+	return websocket.JSON.Send(t.Socket, msg)
+}
+
+// Close tears down the Socket Mode connection
+func (t *Transport) Close() error {
+	t.socketMu.RLock()
+	defer t.socketMu.RUnlock()
+
+	return t.Socket.Close()
+}
+
+// ack acknowledges a Socket Mode envelope so Slack doesn't retry delivery
+func (t *Transport) ack(envelopeID string) {
+	if envelopeID == "" {
+		return
+	}
+
+	t.socketMu.RLock()
+	defer t.socketMu.RUnlock()
+
+	if err := websocket.JSON.Send(t.Socket, socketModeAck{EnvelopeID: envelopeID}); err != nil {
+		t.logger.Error("unable to ack envelope", "envelope_id", envelopeID, "error", err)
+	}
+}
+
+// Receive blocks for the next envelope on the Socket Mode connection,
+// acking and routing it, and returns the next hanu.Message to dispatch
+func (t *Transport) Receive() (hanu.Message, error) {
+	for {
+		var env socketModeEnvelope
+		if err := websocket.JSON.Receive(t.Socket, &env); err != nil {
+			return hanu.Message{}, err
+		}
+
+		t.ack(env.EnvelopeID)
+
+		switch env.Type {
+		case "pong":
+			select {
+			case t.pong <- struct{}{}:
+			default:
+			}
+		case envelopeTypeHello:
+			// nothing to do, connection is ready
+		case envelopeTypeDisconnect:
+			t.logger.Info("received disconnect envelope, reconnecting through apps.connections.open")
+			if err := t.Connect(); err != nil {
+				return hanu.Message{}, fmt.Errorf("Failed to reconnect after disconnect envelope: %v", err)
+			}
+		case envelopeTypeEventsAPI:
+			msg, ok := t.decodeEventsAPI(env.Payload)
+			if ok {
+				return msg, nil
+			}
+		case envelopeTypeSlashCommands:
+			t.handleSlashCommand(env.Payload)
+		case envelopeTypeInteractive:
+			t.dispatchInteractive(env.Payload)
+		}
+	}
+}
+
+func (t *Transport) decodeEventsAPI(raw json.RawMessage) (hanu.Message, bool) {
+	var payload eventsAPIPayload
+	if err := json.Unmarshal(raw, &payload); err != nil {
+		t.logger.Error("unable to unmarshal events_api payload", "error", err)
+		return hanu.Message{}, false
+	}
+
+	var msg hanu.Message
+	if err := json.Unmarshal(payload.Event, &msg); err != nil {
+		t.logger.Error("unable to unmarshal events_api event", "error", err)
+		return hanu.Message{}, false
+	}
+
+	// app_mention covers public mentions, message covers message.im
+	// direct messages (and anything else hanu.Bot decides isn't relevant)
+	switch msg.Type {
+	case "app_mention", "message":
+		return msg, true
+	default:
+		return hanu.Message{}, false
+	}
+}
+
+func (t *Transport) handleSlashCommand(raw json.RawMessage) {
+	var payload hanu.SlashCommand
+	if err := json.Unmarshal(raw, &payload); err != nil {
+		t.logger.Error("unable to unmarshal slash_commands payload", "error", err)
+		return
+	}
+
+	handler, ok := t.slashCommands[payload.Command]
+	if !ok {
+		return
+	}
+
+	t.safeGo("slash_command:"+payload.Command, func() { handler(payload) })
+}
+
+// safeGo runs fn in its own goroutine with a recover, so a panic in a
+// slash command, block action, or view submission handler is logged
+// instead of taking down the whole process. These handlers are
+// dispatched directly by Transport rather than through Bot.wrap, so
+// they don't get hanu's Recovery middleware for free.
+func (t *Transport) safeGo(name string, fn func()) {
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				t.logger.Error("panic in handler", "handler", name, "panic", r)
+			}
+		}()
+
+		fn()
+	}()
+}
+
+// SlashCommand registers a handler for a `/command` slash command
+// delivered through the `slash_commands` Socket Mode envelope, satisfying
+// hanu.SlashCommandRegistrar
+func (t *Transport) SlashCommand(cmd string, handler hanu.SlashCommandHandler) {
+	t.slashCommands[cmd] = handler
+}
+
+// InteractiveHandler registers a handler for an `interactive` Socket Mode
+// envelope, keyed by its `type` field (e.g. `shortcut`, `message_action`),
+// satisfying hanu.InteractiveHandlerRegistrar. `block_actions` and
+// `view_submission` payloads go to OnBlockAction and OnViewSubmission
+// handlers instead and never reach a handler registered here.
+func (t *Transport) InteractiveHandler(kind string, handler hanu.InteractiveHandler) {
+	t.interactiveHandlers[kind] = handler
+}